@@ -0,0 +1,194 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/cli/browser"
+	"github.com/cli/go-gh"
+	"github.com/urfave/cli/v2"
+)
+
+func starCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "star",
+		Usage:     "star one or more repositories",
+		ArgsUsage: "<owner/repo>...",
+		Action:    star,
+	}
+}
+
+func unstarCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "unstar",
+		Usage:     "remove stars from one or more repositories",
+		ArgsUsage: "<owner/repo>...",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:    "yes",
+				Aliases: []string{"y"},
+				Usage:   "don't prompt for confirmation",
+			},
+		},
+		Action: unstar,
+	}
+}
+
+func openCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "open",
+		Usage:     "open a starred repository in your browser",
+		ArgsUsage: "<owner/repo>",
+		Action:    openRepo,
+	}
+}
+
+func syncCommand() *cli.Command {
+	return &cli.Command{
+		Name:   "sync",
+		Usage:  "refresh the persistent cache and report newly starred/unstarred repositories",
+		Action: syncRepos,
+	}
+}
+
+func star(ctx *cli.Context) error {
+	fullNames := ctx.Args().Slice()
+	if len(fullNames) == 0 {
+		return fmt.Errorf("star requires at least one owner/repo argument")
+	}
+	return starOrUnstar(ctx.Context, ctx.Int("batch-size"), fullNames, http.MethodPut)
+}
+
+func unstar(ctx *cli.Context) error {
+	fullNames := ctx.Args().Slice()
+	if len(fullNames) == 0 {
+		return fmt.Errorf("unstar requires at least one owner/repo argument")
+	}
+
+	if !ctx.Bool("yes") && !confirm(fmt.Sprintf("Unstar %d repositories?", len(fullNames))) {
+		fmt.Println("aborted")
+		return nil
+	}
+
+	return starOrUnstar(ctx.Context, ctx.Int("batch-size"), fullNames, http.MethodDelete)
+}
+
+// starOrUnstar stars or unstars a batch of repositories, collecting
+// per-repository errors instead of failing the whole batch on the first
+// one, then invalidates both the in-memory and persistent caches so the
+// next listing reflects the change. Concurrency is bounded by batchSize,
+// the same --batch-size knob getReposPerPageBatch uses, so a large batch of
+// owner/repo arguments doesn't fork one gh subprocess per argument.
+func starOrUnstar(ctx context.Context, batchSize int, fullNames []string, method string) error {
+	if batchSize < 1 {
+		batchSize = 1
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, batchSize)
+	errCh := make(chan error, len(fullNames))
+
+	for _, fullName := range fullNames {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(fullName string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if _, _, err := gh.Exec("api", "-X", method, fmt.Sprintf("user/starred/%s", fullName)); err != nil {
+				errCh <- fmt.Errorf("%s: %w", fullName, err)
+			}
+		}(fullName)
+	}
+	wg.Wait()
+	close(errCh)
+
+	var failures []string
+	for err := range errCh {
+		failures = append(failures, err.Error())
+	}
+
+	cachedRepositories = nil
+	clearCacheFile() //nolint:errcheck
+
+	if len(failures) > 0 {
+		return fmt.Errorf("%d of %d repositories failed:\n%s", len(failures), len(fullNames), strings.Join(failures, "\n"))
+	}
+	return nil
+}
+
+func openRepo(ctx *cli.Context) error {
+	args := ctx.Args().Slice()
+	if len(args) != 1 {
+		return fmt.Errorf("open requires exactly one owner/repo argument")
+	}
+
+	fetchCtx, cancel := fetchContext(ctx)
+	defer cancel()
+
+	starredRepos, err := getRepos(fetchCtx, ctx, true)
+	if err != nil {
+		return err
+	}
+
+	for _, r := range starredRepos {
+		if r.FullName == args[0] {
+			return browser.OpenURL(r.HtmlURL)
+		}
+	}
+	return fmt.Errorf("%s is not among your starred repositories", args[0])
+}
+
+// syncRepos force-refreshes the persistent cache (bypassing --cache-ttl)
+// and reports which starred repositories appeared or disappeared since the
+// previous cache snapshot.
+func syncRepos(ctx *cli.Context) error {
+	before, err := loadCacheFile()
+	if err != nil {
+		return err
+	}
+	beforeNames := map[string]bool{}
+	for _, r := range before.Repos {
+		beforeNames[r.FullName] = true
+	}
+
+	login, _ := currentLogin()
+	cachedRepositories = nil
+
+	fetchCtx, cancel := fetchContext(ctx)
+	defer cancel()
+
+	after, err := refreshRepos(fetchCtx, ctx, login, ctx.Bool("no-cache"))
+	if err != nil {
+		return err
+	}
+	afterNames := map[string]bool{}
+	for _, r := range after {
+		afterNames[r.FullName] = true
+	}
+
+	for _, r := range after {
+		if !beforeNames[r.FullName] {
+			fmt.Printf("+ %s\n", r.FullName)
+		}
+	}
+	for name := range beforeNames {
+		if !afterNames[name] {
+			fmt.Printf("- %s\n", name)
+		}
+	}
+
+	return nil
+}
+
+func confirm(prompt string) bool {
+	fmt.Printf("%s [y/N] ", prompt)
+	reader := bufio.NewReader(os.Stdin)
+	answer, _ := reader.ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cli/go-gh"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	defaultCacheTTL = 24 * time.Hour
+	cacheFileName   = "stars.json"
+)
+
+// cachedPage is one page of the starred-repositories listing, stored with the
+// ETag GitHub returned for it so a later refresh can send If-None-Match and
+// skip re-downloading pages that haven't changed.
+type cachedPage struct {
+	ETag  string       `json:"etag"`
+	Repos []repository `json:"repos"`
+}
+
+// cacheFile is the on-disk representation of the persistent star cache,
+// stored under os.UserCacheDir()/gh-starred/stars.json. It's scoped to the
+// authenticated user: a cache built under a different login is treated as
+// stale.
+type cacheFile struct {
+	Login     string                `json:"login"`
+	FetchedAt time.Time             `json:"fetched_at"`
+	Repos     []repository          `json:"repos"`
+	Pages     map[string]cachedPage `json:"pages"`
+
+	mu sync.Mutex
+}
+
+func pageCacheKey(page, perPage int) string {
+	return fmt.Sprintf("%d:%d", page, perPage)
+}
+
+func (c *cacheFile) page(page, perPage int) cachedPage {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.Pages[pageCacheKey(page, perPage)]
+}
+
+func (c *cacheFile) setPage(page, perPage int, p cachedPage) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.Pages == nil {
+		c.Pages = map[string]cachedPage{}
+	}
+	c.Pages[pageCacheKey(page, perPage)] = p
+}
+
+func (c *cacheFile) isFresh(login string, ttl time.Duration) bool {
+	return c.Login == login && time.Since(c.FetchedAt) < ttl
+}
+
+func cacheFilePath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, COMMAND_NAME, cacheFileName), nil
+}
+
+// loadCacheFile reads the persistent cache from disk. A missing cache file
+// is not an error: it simply returns an empty cacheFile ready to be filled
+// in by getRepos.
+func loadCacheFile() (*cacheFile, error) {
+	path, err := cacheFilePath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &cacheFile{Pages: map[string]cachedPage{}}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var c cacheFile
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, err
+	}
+	if c.Pages == nil {
+		c.Pages = map[string]cachedPage{}
+	}
+	return &c, nil
+}
+
+func saveCacheFile(c *cacheFile) error {
+	path, err := cacheFilePath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+func clearCacheFile() error {
+	path, err := cacheFilePath()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// currentLogin returns the login of the user `gh` is authenticated as,
+// which scopes the persistent cache to that account.
+func currentLogin() (string, error) {
+	stdOut, _, err := gh.Exec("api", "user", "--jq", ".login")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(stdOut.String()), nil
+}
+
+func cacheCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "cache",
+		Usage: "inspect or clear the persistent starred-repositories cache",
+		Subcommands: []*cli.Command{
+			{
+				Name:   "clear",
+				Usage:  "delete the persistent cache",
+				Action: cacheClear,
+			},
+			{
+				Name:   "info",
+				Usage:  "show the persistent cache's location, owner, and age",
+				Action: cacheInfo,
+			},
+		},
+	}
+}
+
+func cacheClear(ctx *cli.Context) error {
+	if err := clearCacheFile(); err != nil {
+		return err
+	}
+	cachedRepositories = nil
+	fmt.Println("cache cleared")
+	return nil
+}
+
+func cacheInfo(ctx *cli.Context) error {
+	path, err := cacheFilePath()
+	if err != nil {
+		return err
+	}
+
+	c, err := loadCacheFile()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("path:       %s\n", path)
+	if c.FetchedAt.IsZero() {
+		fmt.Println("status:     empty")
+		return nil
+	}
+	fmt.Printf("login:      %s\n", c.Login)
+	fmt.Printf("repos:      %d\n", len(c.Repos))
+	fmt.Printf("fetched at: %s\n", c.FetchedAt.Format(time.RFC3339))
+	fmt.Printf("age:        %s\n", time.Since(c.FetchedAt).Round(time.Second))
+	return nil
+}
@@ -0,0 +1,20 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCacheFileIsFresh(t *testing.T) {
+	c := &cacheFile{Login: "octocat", FetchedAt: time.Now().Add(-time.Hour)}
+
+	if !c.isFresh("octocat", 2*time.Hour) {
+		t.Error("isFresh should be true within ttl for the same login")
+	}
+	if c.isFresh("octocat", 30*time.Minute) {
+		t.Error("isFresh should be false once older than ttl")
+	}
+	if c.isFresh("someone-else", 2*time.Hour) {
+		t.Error("isFresh should be false for a different login")
+	}
+}
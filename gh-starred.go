@@ -1,17 +1,26 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
+	"net/http"
 	"os"
+	"os/signal"
+	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/c-bata/go-prompt"
 	"github.com/cli/go-gh"
-	"github.com/olekukonko/tablewriter"
+	"github.com/mattn/go-isatty"
+	"github.com/schollz/progressbar/v3"
 	"github.com/urfave/cli/v2"
 )
 
@@ -21,16 +30,28 @@ const (
 
 var (
 	cachedRepositories []repository
+
+	// linkLastPageRe extracts the page number from the rel="last" entry of a
+	// GitHub Link header, e.g. `<...?page=12&per_page=100>; rel="last"`.
+	linkLastPageRe = regexp.MustCompile(`[?&]page=(\d+)[^>]*>; rel="last"`)
 )
 
 type repository struct {
-	Name     string   `json:"name"`
-	FullName string   `json:"full_name"`
-	Topics   []string `json:"topics"`
-	HtmlURL  string   `json:"html_url"`
+	ID          int64    `json:"id"`
+	Name        string   `json:"name"`
+	FullName    string   `json:"full_name"`
+	Description string   `json:"description"`
+	Language    string   `json:"language"`
+	Topics      []string `json:"topics"`
+	Stargazers  int      `json:"stargazers_count"`
+	HtmlURL     string   `json:"html_url"`
+	UpdatedAt   string   `json:"updated_at"`
 }
 
 func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
 	app := &cli.App{
 		Name:  COMMAND_NAME,
 		Usage: "make operations about your starred repositories",
@@ -38,18 +59,19 @@ func main() {
 			{
 				Name:  "repos",
 				Usage: "list your starred repositories",
-				Flags: []cli.Flag{
+				Flags: append([]cli.Flag{
 					&cli.StringSliceFlag{
 						Name:    "topics",
 						Usage:   "topics to filter repositories",
 						Aliases: []string{"t"},
 					},
-				},
+				}, outputFlags()...),
 				Action: repos,
 			},
 			{
 				Name:   "topics",
 				Usage:  "list topics in your starred repositories",
+				Flags:  outputFlags(),
 				Action: topics,
 			},
 			{
@@ -57,6 +79,12 @@ func main() {
 				Usage:  "activate interactive shell mode",
 				Action: shell,
 			},
+			cacheCommand(),
+			searchCommand(),
+			starCommand(),
+			unstarCommand(),
+			openCommand(),
+			syncCommand(),
 		},
 		Flags: []cli.Flag{
 			&cli.IntFlag{
@@ -64,22 +92,45 @@ func main() {
 				Usage: "batch size to retrieve your starred repository",
 				Value: 5,
 			},
+			&cli.DurationFlag{
+				Name:  "timeout",
+				Usage: "timeout for fetching your starred repositories (0 disables the timeout)",
+			},
+			&cli.BoolFlag{
+				Name:  "no-progress",
+				Usage: "don't show a progress bar while fetching your starred repositories",
+			},
+			&cli.BoolFlag{
+				Name:  "silent",
+				Usage: "suppress all non-essential output, including the progress bar",
+			},
+			&cli.DurationFlag{
+				Name:  "cache-ttl",
+				Usage: "how long the persistent cache is considered fresh",
+				Value: defaultCacheTTL,
+			},
+			&cli.BoolFlag{
+				Name:  "no-cache",
+				Usage: "bypass the persistent cache and always hit the GitHub API",
+			},
 		},
 	}
-	if err := app.Run(os.Args); err != nil {
+	if err := app.RunContext(ctx, os.Args); err != nil {
 		log.Fatal(err)
 	}
 }
 
 func repos(ctx *cli.Context) error {
-	starredRepos, err := getRepos(ctx.Int("batch-size"), true)
+	fetchCtx, cancel := fetchContext(ctx)
+	defer cancel()
+
+	starredRepos, err := getRepos(fetchCtx, ctx, true)
 	if err != nil {
 		return err
 	}
 
 	topics := ctx.StringSlice("topics")
-	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader([]string{"Name", "URL"})
+	filtered := make([]repository, 0, len(starredRepos))
 	for _, repo := range starredRepos {
 		shouldPrint := false
 		if len(topics) == 0 {
@@ -98,29 +149,27 @@ func repos(ctx *cli.Context) error {
 			}
 		}
 		if shouldPrint {
-			table.Append([]string{repo.Name, repo.HtmlURL})
+			filtered = append(filtered, repo)
 		}
 	}
 
-	table.Render()
-	return nil
+	return renderRepos(ctx, filtered)
 }
 
 func topics(ctx *cli.Context) error {
-	topics, err := getTopics(ctx.Int("batch-size"))
+	fetchCtx, cancel := fetchContext(ctx)
+	defer cancel()
+
+	topics, err := getTopics(fetchCtx, ctx)
 	if err != nil {
 		return err
 	}
 
-	for _, topic := range topics {
-		fmt.Println(topic)
-	}
-
-	return nil
+	return renderTopics(ctx, topics)
 }
 
-func getTopics(batchSize int) ([]string, error) {
-	starredRepos, err := getRepos(batchSize, true)
+func getTopics(ctx context.Context, cliCtx *cli.Context) ([]string, error) {
+	starredRepos, err := getRepos(ctx, cliCtx, true)
 	if err != nil {
 		return nil, err
 	}
@@ -155,7 +204,7 @@ type AppExecuter struct {
 func (e *AppExecuter) execute(in string) {
 	args := []string{COMMAND_NAME}
 	args = append(args, strings.Fields(in)...)
-	e.ctx.App.Run(args) //nolint:errcheck
+	e.ctx.App.RunContext(e.ctx.Context, args) //nolint:errcheck
 }
 
 type AppCompleter struct {
@@ -186,7 +235,7 @@ func (c *AppCompleter) complete(in prompt.Document) []prompt.Suggest {
 
 	// complete by repository topics
 	if isAtTopic {
-		topics, _ := getTopics(c.ctx.Int("batch-size"))
+		topics, _ := getTopics(c.ctx.Context, c.ctx)
 		s := []prompt.Suggest{}
 		for _, topic := range topics {
 			s = append(s, prompt.Suggest{
@@ -196,6 +245,19 @@ func (c *AppCompleter) complete(in prompt.Document) []prompt.Suggest {
 		return prompt.FilterFuzzy(s, in.GetWordBeforeCursor(), true)
 	}
 
+	// complete the owner/repo positional argument from cached full names
+	if cmdWord == "star" || cmdWord == "unstar" || cmdWord == "open" {
+		starredRepos, _ := getRepos(c.ctx.Context, c.ctx, true)
+		s := []prompt.Suggest{}
+		for _, repo := range starredRepos {
+			s = append(s, prompt.Suggest{
+				Text:        repo.FullName,
+				Description: repo.Description,
+			})
+		}
+		return prompt.FilterFuzzy(s, in.GetWordBeforeCursor(), true)
+	}
+
 	// otherwise, complete by flags
 	var cmd *cli.Command
 	for _, c := range c.ctx.App.Commands {
@@ -226,20 +288,64 @@ func (c *AppCompleter) complete(in prompt.Document) []prompt.Suggest {
 	return []prompt.Suggest{}
 }
 
-func getRepos(batchSize int, useCache bool) ([]repository, error) {
-	if cachedRepositories != nil && useCache {
+// fetchContext derives the context used for the lifetime of a single
+// getRepos call: it inherits SIGINT cancellation from the app's root
+// context and, when --timeout is set, applies an additional deadline. The
+// returned cancel func must be called once the fetch is done to release the
+// timer; it's a no-op when --timeout isn't set.
+func fetchContext(ctx *cli.Context) (context.Context, context.CancelFunc) {
+	timeout := ctx.Duration("timeout")
+	if timeout <= 0 {
+		return ctx.Context, func() {}
+	}
+	return context.WithTimeout(ctx.Context, timeout)
+}
+
+func getRepos(ctx context.Context, cliCtx *cli.Context, useCache bool) ([]repository, error) {
+	noCache := cliCtx.Bool("no-cache")
+	if cachedRepositories != nil && useCache && !noCache {
 		return cachedRepositories, nil
 	}
 
+	disk, login, err := loadFreshCache(cliCtx, noCache)
+	if err != nil {
+		return nil, err
+	}
+	if disk != nil {
+		cachedRepositories = disk.Repos
+		return disk.Repos, nil
+	}
+
+	return refreshRepos(ctx, cliCtx, login, noCache)
+}
+
+// refreshRepos always hits the GitHub API (conditionally, via per-page
+// ETags when the disk cache isn't bypassed) regardless of --cache-ttl
+// freshness, and persists the result unless noCache is set. Used both by a
+// stale getRepos and by
+// `sync`, which needs an unconditional refresh to diff against.
+func refreshRepos(ctx context.Context, cliCtx *cli.Context, login string, noCache bool) ([]repository, error) {
+	batchSize := cliCtx.Int("batch-size")
 	perPage := 100
+
+	bar := newProgressBar(cliCtx)
+	defer bar.Finish() //nolint:errcheck
+
+	disk := &cacheFile{Pages: map[string]cachedPage{}}
+	if !noCache {
+		if stale, err := loadCacheFile(); err == nil {
+			disk = stale
+		}
+	}
+
 	starredRepos := []repository{}
 	for i := 1; ; i += batchSize {
 		var repos []repository
 		var err error
 		if batchSize == 1 {
-			repos, err = getReposPerPage(i, perPage)
+			repos, err = getReposPerPage(ctx, i, perPage, bar, disk)
 		} else {
-			repos, err = getReposPerPageBatch(i, perPage, batchSize)
+			repos, err = getReposPerPageBatch(ctx, i, perPage, batchSize, bar, disk)
 		}
 		if err != nil {
 			return nil, err
@@ -251,41 +357,201 @@ func getRepos(batchSize int, useCache bool) ([]repository, error) {
 	}
 	cachedRepositories = starredRepos
 
+	if !noCache {
+		disk.Repos = starredRepos
+		disk.FetchedAt = time.Now()
+		disk.Login = login
+		if disk.Login == "" {
+			if l, err := currentLogin(); err == nil {
+				disk.Login = l
+			}
+		}
+		saveCacheFile(disk) //nolint:errcheck
+	}
+
 	return starredRepos, nil
 }
 
-func getReposPerPageBatch(page, perPage, batchSize int) ([]repository, error) {
-	var wg sync.WaitGroup
-	result := []repository{}
+// loadFreshCache returns the persistent cache's repositories when they're
+// still within --cache-ttl for the authenticated user, along with that
+// user's login (so callers that go on to refresh don't have to look it up
+// again). It returns a nil cacheFile when the cache is missing, stale, or
+// --no-cache is set.
+func loadFreshCache(cliCtx *cli.Context, noCache bool) (*cacheFile, string, error) {
+	if noCache {
+		return nil, "", nil
+	}
+
+	c, err := loadCacheFile()
+	if err != nil || c.FetchedAt.IsZero() {
+		return nil, "", nil
+	}
+
+	login, err := currentLogin()
+	if err != nil {
+		return nil, "", nil
+	}
+
+	if c.isFresh(login, cliCtx.Duration("cache-ttl")) {
+		return c, login, nil
+	}
+	return nil, login, nil
+}
+
+// getReposPerPageBatch fetches batchSize pages concurrently starting at
+// page. Each goroutine reports its error (if any) over errCh instead of
+// calling log.Fatalln, and the shared result slice is guarded by a mutex
+// since pages can complete in any order. Each page checks ctx before its
+// request is sent, so a cancelled ctx stops pages that haven't started;
+// requests already in flight run to completion.
+func getReposPerPageBatch(ctx context.Context, page, perPage, batchSize int, bar *progressbar.ProgressBar, cache *cacheFile) ([]repository, error) {
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		result []repository
+	)
+	errCh := make(chan error, batchSize)
+
 	for i := 0; i < batchSize; i++ {
 		wg.Add(1)
 		go func(page, perPage int) {
 			defer wg.Done()
-			repos, err := getReposPerPage(page, perPage)
+			repos, err := getReposPerPage(ctx, page, perPage, bar, cache)
 			if err != nil {
-				log.Fatalln(err)
+				errCh <- err
+				return
 			}
+			mu.Lock()
 			result = append(result, repos...)
+			mu.Unlock()
 		}(page+i, perPage)
 	}
 	wg.Wait()
+	close(errCh)
+
+	for err := range errCh {
+		if err != nil {
+			return nil, err
+		}
+	}
 	return result, nil
 }
 
-func getReposPerPage(page, perPage int) ([]repository, error) {
-	args := []string{
-		"api",
-		fmt.Sprintf("user/starred?page=%d&per_page=%d", page, perPage),
+// getReposPerPage fetches one page, sending an If-None-Match header when
+// cache already holds an ETag for it. A 304 response means GitHub's copy
+// hasn't changed, so the cached page is reused instead of re-parsing a body.
+func getReposPerPage(ctx context.Context, page, perPage int, bar *progressbar.ProgressBar, cache *cacheFile) ([]repository, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var cached cachedPage
+	if cache != nil {
+		cached = cache.page(page, perPage)
 	}
+
+	args := []string{"api", "--include"}
+	if cached.ETag != "" {
+		args = append(args, "-H", fmt.Sprintf("If-None-Match: %s", cached.ETag))
+	}
+	args = append(args, fmt.Sprintf("user/starred?page=%d&per_page=%d", page, perPage))
+
 	stdOut, _, err := gh.Exec(args...)
+	header, body := splitHTTPResponse(stdOut.Bytes())
 	if err != nil {
+		// gh exits non-zero on a 304, so the status is read from the response
+		// head that --include still writes to stdout.
+		head := append(append([]byte{}, header...), "\r\n\r\n"...)
+		if resp, perr := http.ReadResponse(bufio.NewReader(bytes.NewReader(head)), nil); perr == nil && resp.StatusCode == http.StatusNotModified {
+			bar.Add(1) //nolint:errcheck
+			return cached.Repos, nil
+		}
 		return nil, err
 	}
+
+	if page == 1 {
+		if last, ok := lastPageFromLinkHeader(header); ok {
+			bar.ChangeMax(last) //nolint:errcheck
+		}
+	}
+
 	var repos []repository
-	err = json.Unmarshal(stdOut.Bytes(), &repos)
-	if err != nil {
+	if err := json.Unmarshal(body, &repos); err != nil {
 		return nil, err
 	}
 
+	if cache != nil {
+		cache.setPage(page, perPage, cachedPage{ETag: etagFromHeader(header), Repos: repos})
+	}
+
+	bar.Add(1) //nolint:errcheck
+
 	return repos, nil
 }
+
+// splitHTTPResponse splits the raw output of `gh api --include` into its
+// header block and JSON body.
+func splitHTTPResponse(raw []byte) (header, body []byte) {
+	sep := []byte("\r\n\r\n")
+	if i := bytes.Index(raw, sep); i >= 0 {
+		return raw[:i], raw[i+len(sep):]
+	}
+	return nil, raw
+}
+
+// parseHeader parses a raw HTTP header block (as produced by `gh api
+// --include`) into an http.Header, falling back to an empty header if it
+// can't be parsed as one. splitHTTPResponse strips the blank line that ends
+// the block, so it is added back before parsing.
+func parseHeader(header []byte) http.Header {
+	block := append(append([]byte{}, header...), "\r\n\r\n"...)
+	resp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(block)), nil)
+	if err != nil {
+		return http.Header{}
+	}
+	return resp.Header
+}
+
+// lastPageFromLinkHeader parses the rel="last" page number out of a raw
+// HTTP header block, which is how GitHub's paginated API reports the total
+// number of pages for a request.
+func lastPageFromLinkHeader(header []byte) (int, bool) {
+	linkValue := parseHeader(header).Get("Link")
+	if linkValue == "" {
+		return 0, false
+	}
+	m := linkLastPageRe.FindStringSubmatch(linkValue)
+	if m == nil {
+		return 0, false
+	}
+	n, err := strconv.Atoi(m[1])
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
+
+// etagFromHeader extracts the ETag from a raw HTTP header block so it can
+// be stashed in the persistent cache and replayed as If-None-Match later.
+func etagFromHeader(header []byte) string {
+	return parseHeader(header).Get("ETag")
+}
+
+// newProgressBar builds a progress bar rendered to stderr that tracks pages
+// fetched against the estimated total (filled in once the first page's Link
+// header is parsed), along with throughput and ETA. It is a no-op when
+// stderr isn't a TTY or when --no-progress/--silent is set.
+func newProgressBar(ctx *cli.Context) *progressbar.ProgressBar {
+	if ctx.Bool("no-progress") || ctx.Bool("silent") || !isatty.IsTerminal(os.Stderr.Fd()) {
+		return progressbar.DefaultSilent(-1)
+	}
+	return progressbar.NewOptions(-1,
+		progressbar.OptionSetDescription("fetching starred repositories"),
+		progressbar.OptionSetWriter(os.Stderr),
+		progressbar.OptionShowCount(),
+		progressbar.OptionShowIts(),
+		progressbar.OptionSetItsString("page"),
+		progressbar.OptionThrottle(100*time.Millisecond),
+		progressbar.OptionOnCompletion(func() { fmt.Fprintln(os.Stderr) }),
+	)
+}
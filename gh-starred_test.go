@@ -0,0 +1,46 @@
+package main
+
+import "testing"
+
+func TestSplitHTTPResponse(t *testing.T) {
+	raw := []byte("HTTP/1.1 200 OK\r\nETag: \"abc123\"\r\n\r\n" + `[{"id":1}]`)
+	header, body := splitHTTPResponse(raw)
+	if string(body) != `[{"id":1}]` {
+		t.Fatalf("body = %q, want %q", body, `[{"id":1}]`)
+	}
+	if etag := etagFromHeader(header); etag != `"abc123"` {
+		t.Fatalf("etagFromHeader(header) = %q, want %q", etag, `"abc123"`)
+	}
+}
+
+func TestSplitHTTPResponseNoHeader(t *testing.T) {
+	raw := []byte(`[{"id":1}]`)
+	header, body := splitHTTPResponse(raw)
+	if header != nil {
+		t.Fatalf("header = %q, want nil", header)
+	}
+	if string(body) != `[{"id":1}]` {
+		t.Fatalf("body = %q, want %q", body, `[{"id":1}]`)
+	}
+}
+
+func TestLastPageFromLinkHeader(t *testing.T) {
+	raw := []byte("HTTP/1.1 200 OK\r\n" +
+		`Link: <https://api.github.com/user/starred?page=2&per_page=100>; rel="next", <https://api.github.com/user/starred?page=12&per_page=100>; rel="last"` +
+		"\r\n\r\n[]")
+	header, _ := splitHTTPResponse(raw)
+
+	n, ok := lastPageFromLinkHeader(header)
+	if !ok || n != 12 {
+		t.Fatalf("lastPageFromLinkHeader = (%d, %v), want (12, true)", n, ok)
+	}
+}
+
+func TestLastPageFromLinkHeaderMissing(t *testing.T) {
+	raw := []byte("HTTP/1.1 200 OK\r\n\r\n[]")
+	header, _ := splitHTTPResponse(raw)
+
+	if _, ok := lastPageFromLinkHeader(header); ok {
+		t.Fatal("lastPageFromLinkHeader should report ok=false without a Link header")
+	}
+}
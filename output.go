@@ -0,0 +1,290 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/mattn/go-isatty"
+	"github.com/olekukonko/tablewriter"
+	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// defaultFields is the column set rendered when --fields isn't given.
+var defaultFields = []string{"name", "full_name", "url", "description", "topics", "stars", "language", "updated"}
+
+// outputFormat renders a set of rows, each already projected down to
+// fields, in one particular shape. repos, topics, and search all share this
+// single implementation per format rather than keeping their own copies.
+type outputFormat interface {
+	render(w io.Writer, rows []map[string]string, fields []string) error
+}
+
+// outputFlags are shared by every command that lists repositories or topics.
+func outputFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:    "output",
+			Aliases: []string{"o"},
+			Usage:   "output format: table, json, csv, tsv, yaml, or template",
+		},
+		&cli.StringFlag{
+			Name:  "template",
+			Usage: "Go text/template string, used with --output template",
+		},
+		&cli.StringSliceFlag{
+			Name:  "fields",
+			Usage: "fields to include: name, full_name, url, description, topics, stars, language, updated",
+		},
+	}
+}
+
+// renderRepos resolves --output/--fields/--template and writes repos to
+// stdout accordingly.
+func renderRepos(ctx *cli.Context, repos []repository) error {
+	fields := ctx.StringSlice("fields")
+	if len(fields) == 0 {
+		fields = defaultFields
+	}
+	if err := validateFields(fields); err != nil {
+		return err
+	}
+
+	format, err := newOutputFormat(resolveOutputName(ctx), ctx.String("template"))
+	if err != nil {
+		return err
+	}
+	return format.render(os.Stdout, project(repos, fields), fields)
+}
+
+// renderTopics applies the same --output/--template flags as renderRepos,
+// through the same outputFormat implementations. Its rows always have the
+// single "topic" field; --fields is not consulted.
+func renderTopics(ctx *cli.Context, topics []string) error {
+	fields := []string{"topic"}
+	rows := make([]map[string]string, len(topics))
+	for i, t := range topics {
+		rows[i] = map[string]string{"topic": t}
+	}
+
+	format, err := newOutputFormat(resolveOutputName(ctx), ctx.String("template"))
+	if err != nil {
+		return err
+	}
+	return format.render(os.Stdout, rows, fields)
+}
+
+// resolveOutputName applies gh's own convention: default to a human-read
+// table on a TTY, and to pipe-friendly tsv otherwise.
+func resolveOutputName(ctx *cli.Context) string {
+	if name := ctx.String("output"); name != "" {
+		return name
+	}
+	if isatty.IsTerminal(os.Stdout.Fd()) {
+		return "table"
+	}
+	return "tsv"
+}
+
+func newOutputFormat(name, tmpl string) (outputFormat, error) {
+	switch name {
+	case "table":
+		return tableOutput{}, nil
+	case "json":
+		return jsonOutput{}, nil
+	case "yaml":
+		return yamlOutput{}, nil
+	case "csv":
+		return delimitedOutput{comma: ','}, nil
+	case "tsv":
+		return delimitedOutput{comma: '\t'}, nil
+	case "template":
+		if tmpl == "" {
+			return nil, fmt.Errorf("--template is required when --output=template")
+		}
+		t, err := newOutputTemplate(tmpl)
+		if err != nil {
+			return nil, err
+		}
+		return templateOutput{tmpl: t}, nil
+	default:
+		return nil, fmt.Errorf("unknown output format %q", name)
+	}
+}
+
+func newOutputTemplate(tmpl string) (*template.Template, error) {
+	return template.New("output").Funcs(template.FuncMap{
+		"join": strings.Join,
+		"truncate": func(n int, s string) string {
+			r := []rune(s)
+			if len(r) <= n {
+				return s
+			}
+			return string(r[:n]) + "..."
+		},
+	}).Parse(tmpl)
+}
+
+// validateFields rejects any --fields entry outside defaultFields, the same
+// way newOutputFormat already rejects an unknown --output name, instead of
+// silently rendering a blank column.
+func validateFields(fields []string) error {
+	known := make(map[string]bool, len(defaultFields))
+	for _, f := range defaultFields {
+		known[f] = true
+	}
+	for _, f := range fields {
+		if !known[f] {
+			return fmt.Errorf("unknown field %q", f)
+		}
+	}
+	return nil
+}
+
+// fieldValue and fieldHeader are the single source of truth mapping a
+// --fields name to a repository's value and its display header.
+func fieldValue(r repository, field string) string {
+	switch field {
+	case "name":
+		return r.Name
+	case "full_name":
+		return r.FullName
+	case "url":
+		return r.HtmlURL
+	case "description":
+		return r.Description
+	case "topics":
+		return strings.Join(r.Topics, ",")
+	case "stars":
+		return strconv.Itoa(r.Stargazers)
+	case "language":
+		return r.Language
+	case "updated":
+		return r.UpdatedAt
+	default:
+		return ""
+	}
+}
+
+func fieldHeader(field string) string {
+	switch field {
+	case "name":
+		return "Name"
+	case "full_name":
+		return "Full Name"
+	case "url":
+		return "URL"
+	case "description":
+		return "Description"
+	case "topics":
+		return "Topics"
+	case "stars":
+		return "Stars"
+	case "language":
+		return "Language"
+	case "updated":
+		return "Updated"
+	case "topic":
+		return "Topic"
+	default:
+		return field
+	}
+}
+
+// project reduces repos down to the requested fields into the row shape
+// every outputFormat implementation renders from.
+func project(repos []repository, fields []string) []map[string]string {
+	projected := make([]map[string]string, len(repos))
+	for i, r := range repos {
+		row := make(map[string]string, len(fields))
+		for _, f := range fields {
+			row[f] = fieldValue(r, f)
+		}
+		projected[i] = row
+	}
+	return projected
+}
+
+type tableOutput struct{}
+
+func (tableOutput) render(w io.Writer, rows []map[string]string, fields []string) error {
+	headers := make([]string, len(fields))
+	for i, f := range fields {
+		headers[i] = fieldHeader(f)
+	}
+
+	table := tablewriter.NewWriter(w)
+	table.SetHeader(headers)
+	for _, row := range rows {
+		cells := make([]string, len(fields))
+		for i, f := range fields {
+			cells[i] = row[f]
+		}
+		table.Append(cells)
+	}
+	table.Render()
+	return nil
+}
+
+type jsonOutput struct{}
+
+func (jsonOutput) render(w io.Writer, rows []map[string]string, fields []string) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(rows)
+}
+
+type yamlOutput struct{}
+
+func (yamlOutput) render(w io.Writer, rows []map[string]string, fields []string) error {
+	data, err := yaml.Marshal(rows)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+type delimitedOutput struct {
+	comma rune
+}
+
+func (o delimitedOutput) render(w io.Writer, rows []map[string]string, fields []string) error {
+	cw := csv.NewWriter(w)
+	cw.Comma = o.comma
+
+	headers := make([]string, len(fields))
+	for i, f := range fields {
+		headers[i] = fieldHeader(f)
+	}
+	if err := cw.Write(headers); err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		cells := make([]string, len(fields))
+		for i, f := range fields {
+			cells[i] = row[f]
+		}
+		if err := cw.Write(cells); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+type templateOutput struct {
+	tmpl *template.Template
+}
+
+func (o templateOutput) render(w io.Writer, rows []map[string]string, fields []string) error {
+	return o.tmpl.Execute(w, rows)
+}
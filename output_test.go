@@ -0,0 +1,53 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestProject(t *testing.T) {
+	repos := []repository{
+		{Name: "foo", FullName: "a/foo", Topics: []string{"cli", "go"}, Stargazers: 42, Language: "Go"},
+	}
+	rows := project(repos, []string{"name", "topics", "stars", "language"})
+	if len(rows) != 1 {
+		t.Fatalf("project returned %d rows, want 1", len(rows))
+	}
+
+	row := rows[0]
+	if row["name"] != "foo" || row["topics"] != "cli,go" || row["stars"] != "42" || row["language"] != "Go" {
+		t.Fatalf("unexpected row: %#v", row)
+	}
+}
+
+func TestValidateFields(t *testing.T) {
+	if err := validateFields([]string{"name", "stars"}); err != nil {
+		t.Errorf("validateFields rejected known fields: %v", err)
+	}
+	if err := validateFields([]string{"nam"}); err == nil {
+		t.Error("validateFields should reject an unknown field")
+	}
+}
+
+func TestDelimitedOutputRender(t *testing.T) {
+	rows := []map[string]string{{"name": "foo", "stars": "3"}}
+	var buf bytes.Buffer
+	if err := (delimitedOutput{comma: ','}).render(&buf, rows, []string{"name", "stars"}); err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	if want := "Name,Stars\nfoo,3\n"; buf.String() != want {
+		t.Fatalf("render = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestJSONOutputRender(t *testing.T) {
+	rows := []map[string]string{{"name": "foo"}}
+	var buf bytes.Buffer
+	if err := (jsonOutput{}).render(&buf, rows, []string{"name"}); err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	if !strings.Contains(buf.String(), `"name": "foo"`) {
+		t.Fatalf("render = %q, want it to contain \"name\": \"foo\"", buf.String())
+	}
+}
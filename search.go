@@ -0,0 +1,420 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+// notKeywordRe normalizes the "NOT foo" spelling of negation to "-foo" so
+// the rest of the parser only has to handle one form.
+var notKeywordRe = regexp.MustCompile(`(?i)\bNOT\s+`)
+
+const searchIndexFileName = "index.json"
+
+// searchIndex is a trigram inverted index over the cached starred
+// repositories: every lowercased 3-rune gram that appears in an indexed
+// field maps to the deduplicated list of repository IDs containing it, so a
+// query term's candidate set is the intersection of its grams' posting
+// lists. Repository IDs are narrowed to uint32, which comfortably covers
+// GitHub's current ID space.
+type searchIndex struct {
+	FetchedAt time.Time             `json:"fetched_at"`
+	Postings  map[string][]uint32   `json:"postings"`
+	Repos     map[uint32]repository `json:"repos"`
+}
+
+// searchTerm is one token of a parsed search query: either a bare text term
+// matched fuzzily across all fields, or a field-qualified term such as
+// topic:kubernetes, lang:go, or stars:>1000.
+type searchTerm struct {
+	Field   string // "", "topic", "lang", or "stars"
+	Value   string
+	Negate  bool
+	StarsOp byte // '>', '<', or '=' (stars field only)
+	StarsN  int
+}
+
+// searchQuery is a tiny disjunctive-normal-form expression: Groups are
+// OR'd together, and the terms within a group are AND'd.
+type searchQuery struct {
+	Groups [][]searchTerm
+}
+
+func searchCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "search",
+		Usage:     "search your starred repositories with a local index",
+		ArgsUsage: "<query>",
+		Description: "Supports field-qualified terms (topic:kubernetes, lang:go, stars:>1000),\n" +
+			"negation (-term or NOT term), and OR between groups of terms.",
+		Flags:  outputFlags(),
+		Action: search,
+	}
+}
+
+func search(ctx *cli.Context) error {
+	queryStr := strings.Join(ctx.Args().Slice(), " ")
+	if strings.TrimSpace(queryStr) == "" {
+		return fmt.Errorf("search requires a query, e.g. %s search topic:kubernetes lang:go", COMMAND_NAME)
+	}
+
+	cache, err := loadCacheFile()
+	if err != nil {
+		return err
+	}
+	if cache.FetchedAt.IsZero() {
+		fetchCtx, cancel := fetchContext(ctx)
+		_, err := getRepos(fetchCtx, ctx, true)
+		cancel()
+		if err != nil {
+			return err
+		}
+		if cache, err = loadCacheFile(); err != nil {
+			return err
+		}
+	}
+
+	idx, err := loadOrBuildSearchIndex(cache)
+	if err != nil {
+		return err
+	}
+
+	query := parseSearchQuery(queryStr)
+	results := idx.search(query)
+
+	return renderRepos(ctx, results)
+}
+
+func searchIndexPath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, COMMAND_NAME, searchIndexFileName), nil
+}
+
+// loadOrBuildSearchIndex reuses the persisted index when it was built from
+// the same cache snapshot (same FetchedAt), and otherwise rebuilds and
+// re-persists it.
+func loadOrBuildSearchIndex(cache *cacheFile) (*searchIndex, error) {
+	path, err := searchIndexPath()
+	if err != nil {
+		return nil, err
+	}
+
+	if data, err := os.ReadFile(path); err == nil {
+		var idx searchIndex
+		if err := json.Unmarshal(data, &idx); err == nil && idx.FetchedAt.Equal(cache.FetchedAt) {
+			return &idx, nil
+		}
+	}
+
+	idx := buildSearchIndex(cache.Repos)
+	idx.FetchedAt = cache.FetchedAt
+	if data, err := json.Marshal(idx); err == nil {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err == nil {
+			os.WriteFile(path, data, 0o600) //nolint:errcheck
+		}
+	}
+	return idx, nil
+}
+
+func buildSearchIndex(repos []repository) *searchIndex {
+	idx := &searchIndex{
+		Postings: map[string][]uint32{},
+		Repos:    map[uint32]repository{},
+	}
+	for _, r := range repos {
+		id := uint32(r.ID)
+		idx.Repos[id] = r
+
+		seen := map[string]bool{}
+		for _, field := range indexableFields(r) {
+			for _, g := range trigrams(field) {
+				if seen[g] {
+					continue
+				}
+				seen[g] = true
+				idx.Postings[g] = append(idx.Postings[g], id)
+			}
+		}
+	}
+	return idx
+}
+
+func indexableFields(r repository) []string {
+	fields := []string{r.Name, r.FullName, r.Description, r.Language}
+	return append(fields, r.Topics...)
+}
+
+func trigrams(s string) []string {
+	runes := []rune(strings.ToLower(s))
+	if len(runes) == 0 {
+		return nil
+	}
+	if len(runes) < 3 {
+		return []string{string(runes)}
+	}
+	grams := make([]string, 0, len(runes)-2)
+	for i := 0; i+3 <= len(runes); i++ {
+		grams = append(grams, string(runes[i:i+3]))
+	}
+	return grams
+}
+
+// parseSearchQuery turns a raw query string into AND/OR/NOT groups. "NOT foo"
+// is normalized to "-foo" so both spellings share one negation path.
+func parseSearchQuery(raw string) searchQuery {
+	raw = notKeywordRe.ReplaceAllString(raw, "-")
+
+	var q searchQuery
+	for _, orPart := range splitOnKeyword(raw, "OR") {
+		var group []searchTerm
+		for _, tok := range strings.Fields(orPart) {
+			group = append(group, parseSearchTerm(tok))
+		}
+		if len(group) > 0 {
+			q.Groups = append(q.Groups, group)
+		}
+	}
+	return q
+}
+
+func splitOnKeyword(raw, keyword string) []string {
+	var parts []string
+	var cur []string
+	for _, f := range strings.Fields(raw) {
+		if f == keyword {
+			parts = append(parts, strings.Join(cur, " "))
+			cur = nil
+			continue
+		}
+		cur = append(cur, f)
+	}
+	return append(parts, strings.Join(cur, " "))
+}
+
+func parseSearchTerm(tok string) searchTerm {
+	t := searchTerm{}
+	if strings.HasPrefix(tok, "-") && len(tok) > 1 {
+		t.Negate = true
+		tok = tok[1:]
+	}
+
+	if i := strings.Index(tok, ":"); i > 0 {
+		field, value := strings.ToLower(tok[:i]), tok[i+1:]
+		switch field {
+		case "topic":
+			t.Field, t.Value = "topic", strings.ToLower(value)
+			return t
+		case "lang", "language":
+			t.Field, t.Value = "lang", strings.ToLower(value)
+			return t
+		case "stars":
+			t.Field = "stars"
+			t.StarsOp, t.StarsN = parseStarsValue(value)
+			return t
+		}
+	}
+
+	t.Value = strings.ToLower(tok)
+	return t
+}
+
+func parseStarsValue(v string) (byte, int) {
+	op := byte('=')
+	switch {
+	case strings.HasPrefix(v, ">"):
+		op, v = '>', v[1:]
+	case strings.HasPrefix(v, "<"):
+		op, v = '<', v[1:]
+	}
+	n, _ := strconv.Atoi(v)
+	return op, n
+}
+
+// search evaluates the query against the index: candidates are gathered per
+// OR group via trigram-posting intersection, verified exactly against the
+// repository's fields, then ranked by how many fields matched and finally by
+// star count.
+func (idx *searchIndex) search(q searchQuery) []repository {
+	matched := map[uint32]bool{}
+	for _, group := range q.Groups {
+		for id := range idx.candidateIDs(group) {
+			if matched[id] {
+				continue
+			}
+			r, ok := idx.Repos[id]
+			if !ok {
+				continue
+			}
+			if matchesGroup(r, group) {
+				matched[id] = true
+			}
+		}
+	}
+
+	results := make([]repository, 0, len(matched))
+	for id := range matched {
+		results = append(results, idx.Repos[id])
+	}
+
+	terms := bareTerms(q)
+	sort.Slice(results, func(i, j int) bool {
+		mi, mj := matchingFieldCount(results[i], terms), matchingFieldCount(results[j], terms)
+		if mi != mj {
+			return mi > mj
+		}
+		return results[i].Stargazers > results[j].Stargazers
+	})
+	return results
+}
+
+// candidateIDs intersects the trigram postings of every non-negated,
+// non-numeric term in an AND group. Negated terms can only narrow a
+// positive candidate set down (via matchesGroup's verification pass), never
+// build one: a query like "-deprecated" must start from every repository,
+// not from the repositories matching "deprecated". A group with no positive
+// text term (stars:.. only, or NOT-only) likewise starts from everything.
+func (idx *searchIndex) candidateIDs(group []searchTerm) map[uint32]bool {
+	var candidates map[uint32]bool
+	for _, t := range group {
+		if t.Field == "stars" || t.Negate {
+			continue
+		}
+		ids := idx.idsMatchingTrigrams(t.Value)
+		if candidates == nil {
+			candidates = ids
+		} else {
+			candidates = intersectIDs(candidates, ids)
+		}
+	}
+	if candidates == nil {
+		candidates = make(map[uint32]bool, len(idx.Repos))
+		for id := range idx.Repos {
+			candidates[id] = true
+		}
+	}
+	return candidates
+}
+
+func (idx *searchIndex) idsMatchingTrigrams(value string) map[uint32]bool {
+	grams := trigrams(value)
+	// A value shorter than a trigram can sit inside a longer field that has no
+	// gram for it, so every repository stays a candidate for verification.
+	if len([]rune(value)) < 3 {
+		ids := make(map[uint32]bool, len(idx.Repos))
+		for id := range idx.Repos {
+			ids[id] = true
+		}
+		return ids
+	}
+
+	counts := map[uint32]int{}
+	for _, g := range grams {
+		seen := map[uint32]bool{}
+		for _, id := range idx.Postings[g] {
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			counts[id]++
+		}
+	}
+
+	ids := map[uint32]bool{}
+	for id, c := range counts {
+		if c == len(grams) {
+			ids[id] = true
+		}
+	}
+	return ids
+}
+
+func intersectIDs(a, b map[uint32]bool) map[uint32]bool {
+	result := map[uint32]bool{}
+	for id := range a {
+		if b[id] {
+			result[id] = true
+		}
+	}
+	return result
+}
+
+func matchesGroup(r repository, group []searchTerm) bool {
+	for _, t := range group {
+		if matchesTerm(r, t) == t.Negate {
+			return false
+		}
+	}
+	return true
+}
+
+func matchesTerm(r repository, t searchTerm) bool {
+	switch t.Field {
+	case "topic":
+		for _, topic := range r.Topics {
+			if strings.EqualFold(topic, t.Value) {
+				return true
+			}
+		}
+		return false
+	case "lang":
+		return strings.EqualFold(r.Language, t.Value)
+	case "stars":
+		switch t.StarsOp {
+		case '>':
+			return r.Stargazers > t.StarsN
+		case '<':
+			return r.Stargazers < t.StarsN
+		default:
+			return r.Stargazers == t.StarsN
+		}
+	default:
+		return fuzzyContains(r, t.Value)
+	}
+}
+
+func fuzzyContains(r repository, needle string) bool {
+	for _, h := range indexableFields(r) {
+		if strings.Contains(strings.ToLower(h), needle) {
+			return true
+		}
+	}
+	return false
+}
+
+func bareTerms(q searchQuery) []string {
+	var terms []string
+	for _, group := range q.Groups {
+		for _, t := range group {
+			if t.Field == "" && !t.Negate {
+				terms = append(terms, t.Value)
+			}
+		}
+	}
+	return terms
+}
+
+func matchingFieldCount(r repository, terms []string) int {
+	count := 0
+	for _, h := range indexableFields(r) {
+		h = strings.ToLower(h)
+		for _, t := range terms {
+			if strings.Contains(h, t) {
+				count++
+				break
+			}
+		}
+	}
+	return count
+}
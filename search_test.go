@@ -0,0 +1,38 @@
+package main
+
+import "testing"
+
+func fullNames(repos []repository) []string {
+	names := make([]string, len(repos))
+	for i, r := range repos {
+		names[i] = r.FullName
+	}
+	return names
+}
+
+func TestSearchNegatedTerm(t *testing.T) {
+	idx := buildSearchIndex([]repository{
+		{ID: 1, Name: "foo", FullName: "a/foo", Description: "this project is deprecated"},
+		{ID: 2, Name: "bar", FullName: "a/bar", Description: "an actively maintained project"},
+	})
+
+	results := idx.search(parseSearchQuery("-deprecated"))
+	got := fullNames(results)
+	if len(got) != 1 || got[0] != "a/bar" {
+		t.Fatalf("search(-deprecated) = %v, want [a/bar]", got)
+	}
+}
+
+func TestSearchMixedAndNot(t *testing.T) {
+	idx := buildSearchIndex([]repository{
+		{ID: 1, Name: "goproj", FullName: "a/goproj", Language: "Go", Description: "this project is deprecated"},
+		{ID: 2, Name: "goother", FullName: "a/goother", Language: "Go", Description: "an actively maintained project"},
+		{ID: 3, Name: "rbproj", FullName: "a/rbproj", Language: "Ruby", Description: "an actively maintained project"},
+	})
+
+	results := idx.search(parseSearchQuery("go -deprecated"))
+	got := fullNames(results)
+	if len(got) != 1 || got[0] != "a/goother" {
+		t.Fatalf("search(go -deprecated) = %v, want [a/goother]", got)
+	}
+}